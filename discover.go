@@ -0,0 +1,274 @@
+package pluginmanagerbaselib
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"github.com/BurntSushi/toml"
+	"github.com/hashicorp/go-plugin"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// manifestSuffixes 支持的清单文件后缀, 按顺序依次查找
+var manifestSuffixes = []string{".json", ".toml"}
+
+// pluginManifest 插件清单文件结构, 与插件二进制文件同目录下的同名清单文件(.json/.toml)对应.
+// Name字段同时作为AddPlugin校验时使用的已注册插件接口名称.
+type pluginManifest struct {
+	Id               string
+	Name             string
+	PrefixCmdAndArgs []string
+	AllowedProtocols []string
+	HandshakeConfig  plugin.HandshakeConfig
+	SecureConfig     *manifestSecureConfig
+	// VersionedPlugins 按协议版本号声明该插件实现的接口名称集合, 键为协议版本号(字符串形式,
+	// JSON/TOML对象键只能是字符串), 值为接口名称列表. 接口名称需已通过AddPluginTypeInterface
+	// 注册到本次DiscoverPlugins指定的插件类别下, 用于组装PluginInfo.VersionedPlugins
+	VersionedPlugins map[string][]string
+}
+
+// manifestSecureConfig 清单文件中的安全校验配置, Checksum为16进制编码的哈希值
+type manifestSecureConfig struct {
+	Checksum string
+}
+
+// pluginsConfig LoadPluginsFromConfig使用的顶层配置文件结构
+type pluginsConfig struct {
+	Plugins []pluginRootConfig
+}
+
+// pluginRootConfig 单个插件类型的扫描根目录配置
+type pluginRootConfig struct {
+	TypeName string
+	Glob     string
+	Dir      string
+}
+
+// DiscoverPlugins 扫描dir目录下匹配glob规则的插件二进制文件, 读取其同名清单文件(.json/.toml)
+// 并依次注册为pluginTypeName类型下的插件, 返回注册成功的插件信息列表. 扫描中途失败时, 会摘除
+// 本次调用中已注册并启动的插件后再返回错误, 避免调用方在拿到错误的同时还遗留一批不可见、
+// 仍在运行的插件进程
+func DiscoverPlugins(pluginTypeName, glob, dir string) ([]*PluginInfo, error) {
+	files, err := plugin.Discover(glob, dir)
+	if err != nil {
+		return nil, fmt.Errorf("扫描插件目录[%s]失败: %w", dir, err)
+	}
+
+	result := make([]*PluginInfo, 0, len(files))
+	for _, file := range files {
+		if isManifestFile(file) {
+			continue
+		}
+
+		manifestPath, ok := findManifestPath(file)
+		if !ok {
+			rollbackDiscovered(pluginTypeName, result)
+			return nil, fmt.Errorf("未找到插件[%s]对应的清单文件", file)
+		}
+
+		manifest, err := readManifest(manifestPath)
+		if err != nil {
+			rollbackDiscovered(pluginTypeName, result)
+			return nil, fmt.Errorf("解析插件清单文件[%s]失败: %w", manifestPath, err)
+		}
+
+		pluginInfo, err := buildPluginInfoFromManifest(pluginTypeName, file, manifest)
+		if err != nil {
+			rollbackDiscovered(pluginTypeName, result)
+			return nil, fmt.Errorf("构建插件信息[%s]失败: %w", file, err)
+		}
+
+		if err = AddPlugin(pluginTypeName, pluginInfo); err != nil {
+			rollbackDiscovered(pluginTypeName, result)
+			return nil, err
+		}
+
+		result = append(result, pluginInfo)
+	}
+
+	return result, nil
+}
+
+// rollbackDiscovered 摘除本次DiscoverPlugins调用中已注册的插件, 用于中途失败时回滚,
+// 使失败的调用不会遗留调用方无法感知的、仍在运行的插件进程
+func rollbackDiscovered(pluginTypeName string, discovered []*PluginInfo) {
+	for _, info := range discovered {
+		_ = RemovePlugin(pluginTypeName, info.Id)
+	}
+}
+
+// LoadPluginsFromConfig 读取path指向的顶层配置文件(.json/.toml), 按其中列出的每个插件类型的
+// 扫描根目录调用DiscoverPlugins, 使应用可以通过一份配置加载全部插件
+func LoadPluginsFromConfig(path string) ([]*PluginInfo, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("读取插件配置文件[%s]失败: %w", path, err)
+	}
+
+	cfg := &pluginsConfig{}
+	if strings.ToLower(filepath.Ext(path)) == ".toml" {
+		if err = toml.Unmarshal(data, cfg); err != nil {
+			return nil, fmt.Errorf("解析插件配置文件[%s]失败: %w", path, err)
+		}
+	} else if err = json.Unmarshal(data, cfg); err != nil {
+		return nil, fmt.Errorf("解析插件配置文件[%s]失败: %w", path, err)
+	}
+
+	result := make([]*PluginInfo, 0)
+	for _, root := range cfg.Plugins {
+		infos, err := DiscoverPlugins(root.TypeName, root.Glob, root.Dir)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, infos...)
+	}
+
+	return result, nil
+}
+
+// isManifestFile 判断path本身是否是一个清单文件(而非插件二进制文件). glob匹配规则较宽松时
+// (如"plugin*"), plugin.Discover返回的文件列表中也会包含插件二进制文件自身的清单文件, 需要
+// 在处理前将其过滤掉, 避免将清单文件误当作插件二进制文件去查找"清单文件的清单文件"
+func isManifestFile(path string) bool {
+	for _, suffix := range manifestSuffixes {
+		if strings.HasSuffix(path, suffix) {
+			return true
+		}
+	}
+	return false
+}
+
+// findManifestPath 根据插件二进制文件路径查找同名清单文件
+func findManifestPath(pluginFilePath string) (string, bool) {
+	for _, suffix := range manifestSuffixes {
+		p := pluginFilePath + suffix
+		if stat, err := os.Stat(p); err == nil && !stat.IsDir() {
+			return p, true
+		}
+	}
+	return "", false
+}
+
+// readManifest 读取并解析清单文件, 根据文件后缀选择JSON或TOML解码器
+func readManifest(manifestPath string) (*pluginManifest, error) {
+	manifest := &pluginManifest{}
+
+	if strings.ToLower(filepath.Ext(manifestPath)) == ".toml" {
+		if _, err := toml.DecodeFile(manifestPath, manifest); err != nil {
+			return nil, err
+		}
+		return manifest, nil
+	}
+
+	data, err := os.ReadFile(manifestPath)
+	if err != nil {
+		return nil, err
+	}
+	if err = json.Unmarshal(data, manifest); err != nil {
+		return nil, err
+	}
+
+	return manifest, nil
+}
+
+// buildPluginInfoFromManifest 依据清单内容与插件文件的绝对路径构建PluginInfo
+func buildPluginInfoFromManifest(pluginTypeName, pluginFilePath string, manifest *pluginManifest) (*PluginInfo, error) {
+	if manifest.Id == "" {
+		return nil, fmt.Errorf("清单文件缺少Id字段")
+	}
+
+	if manifest.Name == "" {
+		return nil, fmt.Errorf("清单文件缺少Name字段")
+	}
+
+	absPath, err := filepath.Abs(pluginFilePath)
+	if err != nil {
+		return nil, fmt.Errorf("解析插件文件绝对路径失败: %w", err)
+	}
+
+	info := &PluginInfo{
+		Id:               manifest.Id,
+		Name:             manifest.Name,
+		HandshakeConfig:  manifest.HandshakeConfig,
+		PrefixCmdAndArgs: manifest.PrefixCmdAndArgs,
+		PluginFilePath:   absPath,
+	}
+
+	for _, p := range manifest.AllowedProtocols {
+		protocol, err := parseManifestProtocol(p)
+		if err != nil {
+			return nil, err
+		}
+		info.AllowedProtocols = append(info.AllowedProtocols, protocol)
+	}
+
+	if manifest.SecureConfig != nil && manifest.SecureConfig.Checksum != "" {
+		checksum, err := hex.DecodeString(manifest.SecureConfig.Checksum)
+		if err != nil {
+			return nil, fmt.Errorf("解析清单文件中的Checksum失败: %w", err)
+		}
+		info.SecureConfig = &plugin.SecureConfig{
+			Checksum: checksum,
+			Hash:     sha256.New(),
+		}
+	}
+
+	if len(manifest.VersionedPlugins) > 0 {
+		versionedPlugins, err := resolveVersionedPlugins(pluginTypeName, manifest.VersionedPlugins)
+		if err != nil {
+			return nil, err
+		}
+		info.VersionedPlugins = versionedPlugins
+	}
+
+	return info, nil
+}
+
+// resolveVersionedPlugins 依据清单中"协议版本号->接口名称列表"的声明, 从已通过
+// AddPluginTypeInterface注册到pluginTypeName下的接口实现中取出对应项, 组装为
+// PluginInfo.VersionedPlugins可直接使用的结构
+func resolveVersionedPlugins(pluginTypeName string, declared map[string][]string) (map[int]plugin.PluginSet, error) {
+	lock.Lock()
+	registered, ok := pluginTypeMap[pluginTypeName]
+	lock.Unlock()
+	if !ok {
+		return nil, fmt.Errorf("未识别的插件类别: %s", pluginTypeName)
+	}
+
+	result := make(map[int]plugin.PluginSet, len(declared))
+	for versionStr, ifaceNames := range declared {
+		version, err := strconv.Atoi(versionStr)
+		if err != nil {
+			return nil, fmt.Errorf("清单文件中的协议版本号[%s]不是合法整数", versionStr)
+		}
+
+		pluginSet := make(plugin.PluginSet, len(ifaceNames))
+		for _, name := range ifaceNames {
+			impl, ok := registered[name]
+			if !ok {
+				return nil, fmt.Errorf("未被注册的插件接口: %s", name)
+			}
+			pluginSet[name] = impl
+		}
+
+		result[version] = pluginSet
+	}
+
+	return result, nil
+}
+
+// parseManifestProtocol 将清单文件中的协议名称转换为plugin.Protocol
+func parseManifestProtocol(name string) (plugin.Protocol, error) {
+	switch strings.ToLower(name) {
+	case "netrpc", "net/rpc":
+		return plugin.ProtocolNetRPC, nil
+	case "grpc":
+		return plugin.ProtocolGRPC, nil
+	default:
+		return "", fmt.Errorf("未知的协议类型: %s", name)
+	}
+}
@@ -0,0 +1,142 @@
+package pluginmanagerbaselib
+
+import (
+	"github.com/hashicorp/go-plugin"
+	"net/rpc"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// stubPlugin 用于测试resolveVersionedPlugins的占位实现, 不涉及任何真实RPC行为
+type stubPlugin struct{}
+
+func (stubPlugin) Server(*plugin.MuxBroker) (interface{}, error)              { return nil, nil }
+func (stubPlugin) Client(*plugin.MuxBroker, *rpc.Client) (interface{}, error) { return nil, nil }
+
+func TestResolveVersionedPluginsBuildsSetFromRegisteredInterfaces(t *testing.T) {
+	const typeName = "resolveVersionedPluginsTestType"
+	AddPluginTypeInterface(typeName, "ifaceA", stubPlugin{})
+	AddPluginTypeInterface(typeName, "ifaceB", stubPlugin{})
+
+	result, err := resolveVersionedPlugins(typeName, map[string][]string{
+		"1": {"ifaceA"},
+		"2": {"ifaceA", "ifaceB"},
+	})
+	if err != nil {
+		t.Fatalf("resolveVersionedPlugins返回了意外的错误: %v", err)
+	}
+
+	if len(result) != 2 {
+		t.Fatalf("期望解析出2个协议版本, 实际为%d", len(result))
+	}
+	if _, ok := result[1]["ifaceA"]; !ok {
+		t.Fatalf("版本1应包含ifaceA")
+	}
+	if len(result[2]) != 2 {
+		t.Fatalf("版本2应包含2个接口, 实际为%d", len(result[2]))
+	}
+}
+
+func TestResolveVersionedPluginsRejectsUnregisteredInterface(t *testing.T) {
+	const typeName = "resolveVersionedPluginsTestType2"
+	AddPluginTypeInterface(typeName, "ifaceA", stubPlugin{})
+
+	if _, err := resolveVersionedPlugins(typeName, map[string][]string{"1": {"ifaceNotRegistered"}}); err == nil {
+		t.Fatalf("引用未注册的接口名称时应返回错误")
+	}
+}
+
+func TestResolveVersionedPluginsRejectsUnknownType(t *testing.T) {
+	if _, err := resolveVersionedPlugins("resolveVersionedPluginsTestTypeMissing", map[string][]string{"1": {"ifaceA"}}); err == nil {
+		t.Fatalf("未识别的插件类别应返回错误")
+	}
+}
+
+// writeFakePlugin 在dir目录下创建一个同名的插件占位文件与对应清单文件, manifestJSON为空时
+// 不写入清单文件(用于模拟清单缺失的场景)
+func writeFakePlugin(t *testing.T, dir, name, manifestJSON string) {
+	t.Helper()
+
+	if err := os.WriteFile(filepath.Join(dir, name), []byte("fake"), 0755); err != nil {
+		t.Fatalf("创建插件占位文件失败: %v", err)
+	}
+
+	if manifestJSON == "" {
+		return
+	}
+
+	if err := os.WriteFile(filepath.Join(dir, name+".json"), []byte(manifestJSON), 0644); err != nil {
+		t.Fatalf("创建清单文件失败: %v", err)
+	}
+}
+
+func TestDiscoverPluginsRollsBackOnMidScanFailure(t *testing.T) {
+	const typeName = "discoverPluginsRollbackTestType"
+	AddPluginTypeInterface(typeName, "svc", stubPlugin{})
+
+	dir := t.TempDir()
+	writeFakePlugin(t, dir, "pluginA", `{"Id":"pluginA","Name":"svc"}`)
+	writeFakePlugin(t, dir, "pluginB", `{invalid`)
+
+	result, err := DiscoverPlugins(typeName, "plugin*", dir)
+	if err == nil {
+		t.Fatalf("清单文件解析失败时DiscoverPlugins应返回错误")
+	}
+	if result != nil {
+		t.Fatalf("失败时result应为nil, 实际为%v", result)
+	}
+
+	if plugins := Plugins(typeName); len(plugins) != 0 {
+		t.Fatalf("失败后应回滚本次已注册的插件, 实际仍有%d个残留", len(plugins))
+	}
+}
+
+// TestDiscoverPluginsIgnoresOwnManifestFilesInGlob 使用一个同时匹配插件二进制文件与其清单文件
+// 的宽松glob("plugin*"), 验证扫描不会把清单文件自身误判为插件二进制文件而报"未找到清单文件"
+func TestDiscoverPluginsIgnoresOwnManifestFilesInGlob(t *testing.T) {
+	const typeName = "discoverPluginsIgnoresManifestFilesTestType"
+	AddPluginTypeInterface(typeName, "svc", stubPlugin{})
+
+	dir := t.TempDir()
+	writeFakePlugin(t, dir, "pluginA", `{"Id":"pluginA","Name":"svc"}`)
+	writeFakePlugin(t, dir, "pluginB", `{"Id":"pluginB","Name":"svc"}`)
+
+	result, err := DiscoverPlugins(typeName, "plugin*", dir)
+	if err != nil {
+		t.Fatalf("glob同时匹配清单文件时不应报错, 实际返回: %v", err)
+	}
+	if len(result) != 2 {
+		t.Fatalf("期望发现2个插件, 实际为%d", len(result))
+	}
+
+	if plugins := Plugins(typeName); len(plugins) != 2 {
+		t.Fatalf("同一类型下发现的全部插件都应被pluginInfoMap追踪, 实际为%d", len(plugins))
+	}
+}
+
+// TestAddPluginTracksEveryEntryUnderSameType 验证同一pluginTypeName下第二次及以后调用
+// AddPlugin时, pluginInfoMap也会被更新为追加后的切片, 而不是仅第一个插件被持久化
+func TestAddPluginTracksEveryEntryUnderSameType(t *testing.T) {
+	const typeName = "addPluginTracksEveryEntryTestType"
+	AddPluginTypeInterface(typeName, "svc", stubPlugin{})
+
+	dir := t.TempDir()
+	writeFakePlugin(t, dir, "pluginA", "")
+	writeFakePlugin(t, dir, "pluginB", "")
+
+	if err := AddPlugin(typeName, &PluginInfo{Id: "pluginA", Name: "svc", PluginFilePath: filepath.Join(dir, "pluginA")}); err != nil {
+		t.Fatalf("注册pluginA失败: %v", err)
+	}
+	if err := AddPlugin(typeName, &PluginInfo{Id: "pluginB", Name: "svc", PluginFilePath: filepath.Join(dir, "pluginB")}); err != nil {
+		t.Fatalf("注册pluginB失败: %v", err)
+	}
+
+	if plugins := Plugins(typeName); len(plugins) != 2 {
+		t.Fatalf("同一类型下新增的第二个插件也应被pluginInfoMap追踪, 实际为%d", len(plugins))
+	}
+
+	if err := RemovePlugin(typeName, "pluginB"); err != nil {
+		t.Fatalf("第二个插件应能被RemovePlugin找到并移除, 实际返回: %v", err)
+	}
+}
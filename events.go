@@ -0,0 +1,125 @@
+package pluginmanagerbaselib
+
+import (
+	"sync"
+	"time"
+)
+
+// EventHandler 插件生命周期事件观察者, 可通过RegisterEventHandler注册为全局观察者,
+// 也可以赋值给PluginInfo.Handlers仅观察单个插件
+type EventHandler interface {
+	// OnStart 插件启动并完成首次连接后触发
+	OnStart(id string)
+	// OnStop 插件停止时触发, 主动停止时reason为nil, 因异常退出而停止时reason为对应错误
+	OnStop(id string, reason error)
+	// OnHealthCheckFailed 健康检查(Ping)失败或插件进程已退出时触发
+	OnHealthCheckFailed(id string, err error)
+	// OnRestart 插件因健康检查失败被重新拉起时触发, attempt为累计重启次数
+	OnRestart(id string, attempt int)
+	// OnVerifyFailed 插件文件的完整性或身份校验(如HASH校验)失败时触发
+	OnVerifyFailed(id string, err error)
+}
+
+var (
+	eventHandlersLock sync.Mutex
+	eventHandlers     []EventHandler
+)
+
+// RegisterEventHandler 注册一个全局插件事件观察者, 对所有插件类型与实例生效
+func RegisterEventHandler(h EventHandler) {
+	eventHandlersLock.Lock()
+	defer eventHandlersLock.Unlock()
+
+	eventHandlers = append(eventHandlers, h)
+}
+
+// globalEventHandlers 返回当前已注册的全局事件观察者副本
+func globalEventHandlers() []EventHandler {
+	eventHandlersLock.Lock()
+	defer eventHandlersLock.Unlock()
+
+	result := make([]EventHandler, len(eventHandlers))
+	copy(result, eventHandlers)
+	return result
+}
+
+// handlers 返回当前插件需要触发的全部观察者, 全局观察者与插件自身的Handlers都包含在内
+func (p *PluginInfo) handlers() []EventHandler {
+	all := globalEventHandlers()
+	if len(p.Handlers) > 0 {
+		all = append(all, p.Handlers...)
+	}
+	return all
+}
+
+func (p *PluginInfo) emitStart() {
+	for _, h := range p.handlers() {
+		h.OnStart(p.Id)
+	}
+}
+
+func (p *PluginInfo) emitStop(reason error) {
+	for _, h := range p.handlers() {
+		h.OnStop(p.Id, reason)
+	}
+}
+
+func (p *PluginInfo) emitHealthCheckFailed(err error) {
+	for _, h := range p.handlers() {
+		h.OnHealthCheckFailed(p.Id, err)
+	}
+}
+
+func (p *PluginInfo) emitRestart(attempt int) {
+	for _, h := range p.handlers() {
+		h.OnRestart(p.Id, attempt)
+	}
+}
+
+func (p *PluginInfo) emitVerifyFailed(err error) {
+	for _, h := range p.handlers() {
+		h.OnVerifyFailed(p.Id, err)
+	}
+}
+
+// PluginStatus 插件运行状态快照, 供管理端查看而无需访问PluginInfo的非导出字段
+type PluginStatus struct {
+	Id           string
+	Name         string
+	Running      bool
+	LastPing     time.Time
+	RestartCount int
+	LastError    error
+	Unhealthy    bool
+}
+
+// status 生成当前插件的状态快照
+func (p *PluginInfo) status() PluginStatus {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	return PluginStatus{
+		Id:           p.Id,
+		Name:         p.Name,
+		Running:      p.client != nil && !p.stop,
+		LastPing:     p.lastPing,
+		RestartCount: p.restartCount,
+		LastError:    p.err,
+		Unhealthy:    p.unhealthy,
+	}
+}
+
+// Plugins 返回typeName类型下所有插件的当前状态快照
+func Plugins(typeName string) []PluginStatus {
+	lock.Lock()
+	pluginList := pluginInfoMap[typeName]
+	infos := make([]*PluginInfo, len(pluginList))
+	copy(infos, pluginList)
+	lock.Unlock()
+
+	result := make([]PluginStatus, 0, len(infos))
+	for _, info := range infos {
+		result = append(result, info.status())
+	}
+	return result
+}
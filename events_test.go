@@ -0,0 +1,45 @@
+package pluginmanagerbaselib
+
+import "testing"
+
+// recordingHandler 记录收到的OnStop调用, 用于断言close()与stopAndNotify()的触发行为差异
+type recordingHandler struct {
+	stops []error
+}
+
+func (h *recordingHandler) OnStart(id string)                        {}
+func (h *recordingHandler) OnStop(id string, reason error)           { h.stops = append(h.stops, reason) }
+func (h *recordingHandler) OnHealthCheckFailed(id string, err error) {}
+func (h *recordingHandler) OnRestart(id string, attempt int)         {}
+func (h *recordingHandler) OnVerifyFailed(id string, err error)      {}
+
+func TestCloseDoesNotEmitOnStop(t *testing.T) {
+	h := &recordingHandler{}
+	p := &PluginInfo{Id: "test", Handlers: []EventHandler{h}}
+	p.client = nil // 未建立连接: 此处只关注OnStop是否触发, client是否存在不影响该断言
+
+	p.close()
+
+	if len(h.stops) != 0 {
+		t.Fatalf("close()仅终止运行, 不应触发OnStop, 实际触发了%d次", len(h.stops))
+	}
+	if !p.stop {
+		t.Fatalf("close()后p.stop应为true")
+	}
+}
+
+func TestStopAndNotifyEmitsOnStopWithGivenReason(t *testing.T) {
+	h := &recordingHandler{}
+	p := &PluginInfo{Id: "test", Handlers: []EventHandler{h}}
+
+	p.stopAndNotify(nil)
+	if len(h.stops) != 1 || h.stops[0] != nil {
+		t.Fatalf("主动停止应恰好触发一次reason为nil的OnStop, 实际为%v", h.stops)
+	}
+
+	// 已经停止后再次调用不应重复触发OnStop
+	p.stopAndNotify(nil)
+	if len(h.stops) != 1 {
+		t.Fatalf("重复调用stopAndNotify不应重复触发OnStop, 实际触发了%d次", len(h.stops))
+	}
+}
@@ -56,7 +56,9 @@ func AddPlugin(pluginTypeName string, pluginInfo *PluginInfo) error {
 		return fmt.Errorf("未被注册的插件名称: %s", pluginInfo.Name)
 	}
 
+	pluginInfo.typeName = pluginTypeName
 	defer pluginInfo.start()
+
 	pluginList, ok := pluginInfoMap[pluginTypeName]
 	if !ok {
 		pluginInfoMap[pluginTypeName] = []*PluginInfo{pluginInfo}
@@ -71,6 +73,48 @@ func AddPlugin(pluginTypeName string, pluginInfo *PluginInfo) error {
 		}
 	}
 
-	pluginList = append(pluginList, pluginInfo)
+	pluginInfoMap[pluginTypeName] = append(pluginList, pluginInfo)
 	return nil
 }
+
+// RemovePlugin 移除typeName类型下Id为id的插件, 停止其进程并从pluginInfoMap中摘除
+func RemovePlugin(typeName, id string) error {
+	lock.Lock()
+	defer lock.Unlock()
+
+	pluginList, ok := pluginInfoMap[typeName]
+	if !ok {
+		return fmt.Errorf("未识别的插件类别: %s", typeName)
+	}
+
+	for i, p := range pluginList {
+		if p.Id != id {
+			continue
+		}
+
+		p.stopAndNotify(nil)
+		pluginInfoMap[typeName] = append(pluginList[:i:i], pluginList[i+1:]...)
+		return nil
+	}
+
+	return fmt.Errorf("插件[%s]不存在", id)
+}
+
+// findPluginInfo 在pluginInfoMap中查找typeName类型下Id为id的插件信息
+func findPluginInfo(typeName, id string) (*PluginInfo, error) {
+	lock.Lock()
+	defer lock.Unlock()
+
+	pluginList, ok := pluginInfoMap[typeName]
+	if !ok {
+		return nil, fmt.Errorf("未识别的插件类别: %s", typeName)
+	}
+
+	for _, p := range pluginList {
+		if p.Id == id {
+			return p, nil
+		}
+	}
+
+	return nil, fmt.Errorf("插件[%s]不存在", id)
+}
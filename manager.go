@@ -0,0 +1,286 @@
+package pluginmanagerbaselib
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/hashicorp/go-plugin"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+// ManagerOption Manager的可选配置项
+type ManagerOption func(*Manager)
+
+// WithManagerContext 为Manager指定ctx, 取消该ctx会触发其管理的全部插件优雅关闭
+func WithManagerContext(ctx context.Context) ManagerOption {
+	return func(m *Manager) {
+		m.ctx, m.cancel = context.WithCancel(ctx)
+	}
+}
+
+// WithReattachDir 设置ReattachConfig的持久化目录: 插件启动后其pid/地址/网络类型会写入该目录,
+// Manager初始化时读取该目录, 使后续Add同Id插件时可以重新连接到仍在运行的旧进程, 而不是
+// 因宿主进程重启而将其杀死后重新拉起
+func WithReattachDir(dir string) ManagerOption {
+	return func(m *Manager) {
+		m.reattachDir = dir
+	}
+}
+
+// Manager 面向单一插件类型的对象化插件管理器, 在包级全局函数(AddPlugin/RemovePlugin等)之上
+// 提供Add/Remove/List/Get/Dispense/Shutdown这一更符合使用习惯的API, 并支持按插件独立选择
+// net/rpc或gRPC传输协议(由PluginInfo.AllowedProtocols/VersionedPlugins决定)以及进程重连
+type Manager struct {
+	lock        sync.Mutex
+	typeName    string
+	reattachDir string
+	reattached  map[string]*plugin.ReattachConfig
+	ctx         context.Context
+	cancel      context.CancelFunc
+}
+
+// NewManager 创建typeName类型下的插件管理器
+func NewManager(typeName string, opts ...ManagerOption) *Manager {
+	m := &Manager{typeName: typeName}
+
+	for _, opt := range opts {
+		opt(m)
+	}
+
+	if m.ctx == nil {
+		m.ctx, m.cancel = context.WithCancel(context.Background())
+	}
+
+	if m.reattachDir != "" {
+		m.reattached = loadReattachConfigs(m.reattachDir)
+	}
+
+	return m
+}
+
+// Add 注册并启动一个插件. 若其Id在ReattachDir中存有持久化的ReattachConfig, 则连接到该配置
+// 描述的旧进程而不是启动新的插件进程; 该配置是否仍指向存活的进程不做预先检查
+func (m *Manager) Add(info *PluginInfo) error {
+	if info == nil {
+		return fmt.Errorf("插件信息不能为空")
+	}
+
+	m.lock.Lock()
+	reattach, ok := m.reattached[info.Id]
+	m.lock.Unlock()
+	if ok {
+		info.Reattach = reattach
+	}
+
+	if m.reattachDir != "" {
+		// 注册为该插件的事件观察者, 使其每次(重)建立连接(首次启动/崩溃后supervisor重连/
+		// WatchFile热替换)时都会触发OnStart, 从而持续刷新持久化的ReattachConfig, 而不是
+		// 仅在本次Add时写入一次, 让其在进程重启或被替换后就指向一个已经不存在的旧进程
+		info.Handlers = append(info.Handlers, m)
+	}
+
+	if err := AddPlugin(m.typeName, info); err != nil {
+		return err
+	}
+
+	go func() {
+		<-m.ctx.Done()
+		_ = m.Remove(info.Id)
+	}()
+
+	return nil
+}
+
+// Remove 停止并移除一个插件, 同时清理其持久化的ReattachConfig
+func (m *Manager) Remove(id string) error {
+	if err := RemovePlugin(m.typeName, id); err != nil {
+		return err
+	}
+
+	if m.reattachDir != "" {
+		_ = os.Remove(reattachFilePath(m.reattachDir, id))
+	}
+
+	m.lock.Lock()
+	delete(m.reattached, id)
+	m.lock.Unlock()
+
+	return nil
+}
+
+// List 返回当前受管的全部插件状态快照
+func (m *Manager) List() []PluginStatus {
+	return Plugins(m.typeName)
+}
+
+// Get 返回id对应插件当前的底层RPC客户端
+func (m *Manager) Get(id string) (plugin.ClientProtocol, error) {
+	info, err := findPluginInfo(m.typeName, id)
+	if err != nil {
+		return nil, err
+	}
+
+	info.lock.Lock()
+	defer info.lock.Unlock()
+
+	if info.rpcCli == nil {
+		return nil, fmt.Errorf("插件[%s]尚未就绪", id)
+	}
+
+	return info.rpcCli, nil
+}
+
+// Dispense 获取id对应插件中ifaceName接口的实现
+func (m *Manager) Dispense(id, ifaceName string) (interface{}, error) {
+	cli, err := m.Get(id)
+	if err != nil {
+		return nil, err
+	}
+
+	return cli.Dispense(ifaceName)
+}
+
+// Shutdown 取消Manager的ctx, 使其管理的全部插件按Add中注册的回调优雅关闭
+func (m *Manager) Shutdown() {
+	if m.cancel != nil {
+		m.cancel()
+	}
+}
+
+// OnStart 实现EventHandler, 使Manager能够感知到自己名下插件的每一次(重新)启动. 首次Add、
+// supervisor因健康检查失败而重连、以及WatchFile触发的热替换都会经由此处重新持久化
+// ReattachConfig. findPluginInfo会获取包级别的全局锁, 而AddPlugin在持有该锁期间就会同步
+// 触发本插件的首次启动, 故这里异步执行, 避免与AddPlugin尚未释放的锁发生重入死锁
+func (m *Manager) OnStart(id string) {
+	go func() {
+		info, err := findPluginInfo(m.typeName, id)
+		if err != nil {
+			return
+		}
+		m.persist(info)
+	}()
+}
+
+// OnStop EventHandler的其余回调对Manager无意义, 空实现
+func (m *Manager) OnStop(string, error) {}
+
+// OnHealthCheckFailed EventHandler的其余回调对Manager无意义, 空实现
+func (m *Manager) OnHealthCheckFailed(string, error) {}
+
+// OnRestart EventHandler的其余回调对Manager无意义, 空实现
+func (m *Manager) OnRestart(string, int) {}
+
+// OnVerifyFailed EventHandler的其余回调对Manager无意义, 空实现
+func (m *Manager) OnVerifyFailed(string, error) {}
+
+// persist 将info当前的ReattachConfig写入m.reattachDir, 供下次Manager初始化时读取
+func (m *Manager) persist(info *PluginInfo) {
+	info.lock.Lock()
+	client := info.client
+	info.lock.Unlock()
+
+	if client == nil {
+		return
+	}
+
+	cfg := client.ReattachConfig()
+	if cfg == nil {
+		return
+	}
+
+	_ = writeReattachConfig(m.reattachDir, info.Id, cfg)
+}
+
+// persistedReattach ReattachConfig的磁盘持久化结构, net.Addr以network+address两个字符串拆分存储
+type persistedReattach struct {
+	Pid             int
+	Network         string
+	Address         string
+	Protocol        string
+	ProtocolVersion int
+}
+
+// reattachFilePath 返回id对应插件的ReattachConfig持久化文件路径
+func reattachFilePath(dir, id string) string {
+	return filepath.Join(dir, id+".reattach.json")
+}
+
+// writeReattachConfig 将cfg写入dir目录下id对应的持久化文件
+func writeReattachConfig(dir, id string, cfg *plugin.ReattachConfig) error {
+	if cfg.Addr == nil {
+		return fmt.Errorf("ReattachConfig缺少地址信息")
+	}
+
+	data, err := json.Marshal(&persistedReattach{
+		Pid:             cfg.Pid,
+		Network:         cfg.Addr.Network(),
+		Address:         cfg.Addr.String(),
+		Protocol:        string(cfg.Protocol),
+		ProtocolVersion: cfg.ProtocolVersion,
+	})
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(reattachFilePath(dir, id), data, 0644)
+}
+
+// loadReattachConfigs 扫描dir目录下全部持久化文件, 还原为以插件Id为键的ReattachConfig集合
+func loadReattachConfigs(dir string) map[string]*plugin.ReattachConfig {
+	result := make(map[string]*plugin.ReattachConfig)
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return result
+	}
+
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), ".reattach.json") {
+			continue
+		}
+
+		id := strings.TrimSuffix(entry.Name(), ".reattach.json")
+		cfg, err := readReattachConfig(filepath.Join(dir, entry.Name()))
+		if err != nil {
+			continue
+		}
+
+		result[id] = cfg
+	}
+
+	return result
+}
+
+// readReattachConfig 读取并解析单个ReattachConfig持久化文件
+func readReattachConfig(path string) (*plugin.ReattachConfig, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	stored := &persistedReattach{}
+	if err = json.Unmarshal(data, stored); err != nil {
+		return nil, err
+	}
+
+	var addr net.Addr
+	if stored.Network == "unix" {
+		addr, err = net.ResolveUnixAddr("unix", stored.Address)
+	} else {
+		addr, err = net.ResolveTCPAddr(stored.Network, stored.Address)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &plugin.ReattachConfig{
+		Protocol:        plugin.Protocol(stored.Protocol),
+		ProtocolVersion: stored.ProtocolVersion,
+		Addr:            addr,
+		Pid:             stored.Pid,
+	}, nil
+}
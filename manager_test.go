@@ -0,0 +1,152 @@
+package pluginmanagerbaselib
+
+import (
+	"github.com/hashicorp/go-plugin"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"testing"
+)
+
+func TestWriteAndReadReattachConfigRoundTripTCP(t *testing.T) {
+	dir := t.TempDir()
+	cfg := &plugin.ReattachConfig{
+		Protocol:        plugin.ProtocolGRPC,
+		ProtocolVersion: 2,
+		Pid:             4321,
+		Addr:            &net.TCPAddr{IP: net.ParseIP("127.0.0.1"), Port: 9999},
+	}
+
+	if err := writeReattachConfig(dir, "plugin-1", cfg); err != nil {
+		t.Fatalf("写入ReattachConfig失败: %v", err)
+	}
+
+	got, err := readReattachConfig(reattachFilePath(dir, "plugin-1"))
+	if err != nil {
+		t.Fatalf("读取ReattachConfig失败: %v", err)
+	}
+
+	if got.Protocol != cfg.Protocol || got.ProtocolVersion != cfg.ProtocolVersion || got.Pid != cfg.Pid {
+		t.Fatalf("还原的ReattachConfig与原始值不一致: %+v", got)
+	}
+	if got.Addr.Network() != cfg.Addr.Network() || got.Addr.String() != cfg.Addr.String() {
+		t.Fatalf("还原的地址与原始值不一致: %v", got.Addr)
+	}
+}
+
+func TestWriteAndReadReattachConfigRoundTripUnix(t *testing.T) {
+	dir := t.TempDir()
+	cfg := &plugin.ReattachConfig{
+		Protocol: plugin.ProtocolNetRPC,
+		Pid:      1,
+		Addr:     &net.UnixAddr{Name: filepath.Join(dir, "plugin.sock"), Net: "unix"},
+	}
+
+	if err := writeReattachConfig(dir, "plugin-2", cfg); err != nil {
+		t.Fatalf("写入ReattachConfig失败: %v", err)
+	}
+
+	got, err := readReattachConfig(reattachFilePath(dir, "plugin-2"))
+	if err != nil {
+		t.Fatalf("读取ReattachConfig失败: %v", err)
+	}
+
+	if got.Addr.Network() != "unix" || got.Addr.String() != cfg.Addr.String() {
+		t.Fatalf("还原的unix地址与原始值不一致: %v", got.Addr)
+	}
+}
+
+func TestWriteReattachConfigRejectsMissingAddr(t *testing.T) {
+	if err := writeReattachConfig(t.TempDir(), "plugin-3", &plugin.ReattachConfig{}); err == nil {
+		t.Fatalf("ReattachConfig缺少地址时应返回错误")
+	}
+}
+
+func TestLoadReattachConfigsSkipsUnrelatedFiles(t *testing.T) {
+	dir := t.TempDir()
+	cfg := &plugin.ReattachConfig{
+		Protocol: plugin.ProtocolNetRPC,
+		Addr:     &net.TCPAddr{IP: net.ParseIP("127.0.0.1"), Port: 1234},
+	}
+	if err := writeReattachConfig(dir, "plugin-a", cfg); err != nil {
+		t.Fatalf("写入ReattachConfig失败: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "不相关文件.txt"), []byte("noop"), 0644); err != nil {
+		t.Fatalf("写入无关文件失败: %v", err)
+	}
+
+	result := loadReattachConfigs(dir)
+	if len(result) != 1 {
+		t.Fatalf("应只还原出1个ReattachConfig, 实际为%d", len(result))
+	}
+	if _, ok := result["plugin-a"]; !ok {
+		t.Fatalf("应还原出plugin-a对应的ReattachConfig")
+	}
+}
+
+// TestManagerAddRemoveConcurrentMapAccess 复现Add读取m.reattached时不加锁, 与Remove持锁删除
+// 同一map并发执行的场景: 此前Add对m.reattached的读取未加m.lock, 在`go test -race`下会被
+// 探测为对同一map的并发读写. Add现在应与Remove一样持m.lock读取该map
+func TestManagerAddRemoveConcurrentMapAccess(t *testing.T) {
+	const typeName = "managerConcurrentAddRemoveTestType"
+	AddPluginTypeInterface(typeName, "svc", stubPlugin{})
+
+	dir := t.TempDir()
+	pluginPath := filepath.Join(dir, "plugin.bin")
+	if err := os.WriteFile(pluginPath, []byte("fake"), 0755); err != nil {
+		t.Fatalf("创建插件占位文件失败: %v", err)
+	}
+
+	m := NewManager(typeName, WithReattachDir(t.TempDir()))
+
+	var wg sync.WaitGroup
+	for i := 0; i < 50; i++ {
+		id := "plugin-" + strconv.Itoa(i)
+		wg.Add(2)
+		go func(id string) {
+			defer wg.Done()
+			_ = m.Add(&PluginInfo{Id: id, Name: "svc", PluginFilePath: pluginPath})
+		}(id)
+		go func(id string) {
+			defer wg.Done()
+			_ = m.Remove(id)
+		}(id)
+	}
+	wg.Wait()
+}
+
+// TestManagerAddTracksEveryPluginUnderSameType 在AddPlugin的"同类型下第二个及以后的插件
+// 未被写回pluginInfoMap"缺陷修复前, 并发Add 50个同类型下不同Id的插件时, m.List()只会看到
+// 最先写入的那一个; 这里不掺杂并发Remove, 以便确定性地断言全部50个都被追踪到
+func TestManagerAddTracksEveryPluginUnderSameType(t *testing.T) {
+	const typeName = "managerAddTracksEveryPluginTestType"
+	AddPluginTypeInterface(typeName, "svc", stubPlugin{})
+
+	dir := t.TempDir()
+	pluginPath := filepath.Join(dir, "plugin.bin")
+	if err := os.WriteFile(pluginPath, []byte("fake"), 0755); err != nil {
+		t.Fatalf("创建插件占位文件失败: %v", err)
+	}
+
+	m := NewManager(typeName)
+
+	const total = 50
+	var wg sync.WaitGroup
+	for i := 0; i < total; i++ {
+		id := "plugin-" + strconv.Itoa(i)
+		wg.Add(1)
+		go func(id string) {
+			defer wg.Done()
+			if err := m.Add(&PluginInfo{Id: id, Name: "svc", PluginFilePath: pluginPath}); err != nil {
+				t.Errorf("Add(%s)失败: %v", id, err)
+			}
+		}(id)
+	}
+	wg.Wait()
+
+	if plugins := m.List(); len(plugins) != total {
+		t.Fatalf("期望m.List()追踪到全部%d个插件, 实际为%d", total, len(plugins))
+	}
+}
@@ -0,0 +1,86 @@
+package pluginmanagerbaselib
+
+import (
+	"bytes"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// pinnedTLSConfig 依据TrustedServerCerts构建用于证书锁定的TLSConfig. 未设置TrustedServerCerts
+// 时原样返回p.TLSConfig
+func (p *PluginInfo) pinnedTLSConfig() (*tls.Config, error) {
+	if len(p.TrustedServerCerts) == 0 {
+		return p.TLSConfig, nil
+	}
+
+	if p.AutoMTLS {
+		return nil, fmt.Errorf("AutoMTLS模式下暂不支持TrustedServerCerts证书锁定")
+	}
+
+	if p.TLSConfig == nil {
+		return nil, fmt.Errorf("启用TrustedServerCerts前必须先设置TLSConfig")
+	}
+
+	tlsConfig := p.TLSConfig.Clone()
+	tlsConfig.InsecureSkipVerify = true
+	tlsConfig.VerifyPeerCertificate = verifyTrustedServerCert(p.TrustedServerCerts)
+	return tlsConfig, nil
+}
+
+// verifyTrustedServerCert 返回一个校验函数, 用于确认插件服务端出示的叶子证书是否
+// 逐字节包含在trusted集合中
+func verifyTrustedServerCert(trusted [][]byte) func(rawCerts [][]byte, _ [][]*x509.Certificate) error {
+	return func(rawCerts [][]byte, _ [][]*x509.Certificate) error {
+		if len(rawCerts) == 0 {
+			return fmt.Errorf("插件服务端未提供证书")
+		}
+
+		leaf := rawCerts[0]
+		for _, cert := range trusted {
+			if bytes.Equal(leaf, cert) {
+				return nil
+			}
+		}
+
+		return fmt.Errorf("插件服务端证书未被信任")
+	}
+}
+
+// LoadTrustedCertsDir 从dir目录下加载所有PEM编码的证书文件, 返回可直接赋值给
+// PluginInfo.TrustedServerCerts的DER字节切片集合
+func LoadTrustedCertsDir(dir string) ([][]byte, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("读取证书目录[%s]失败: %w", dir, err)
+	}
+
+	result := make([][]byte, 0, len(entries))
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+
+		certPath := filepath.Join(dir, entry.Name())
+		data, err := os.ReadFile(certPath)
+		if err != nil {
+			return nil, fmt.Errorf("读取证书文件[%s]失败: %w", certPath, err)
+		}
+
+		for len(data) > 0 {
+			var block *pem.Block
+			block, data = pem.Decode(data)
+			if block == nil {
+				break
+			}
+			if block.Type == "CERTIFICATE" {
+				result = append(result, block.Bytes)
+			}
+		}
+	}
+
+	return result, nil
+}
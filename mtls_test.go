@@ -0,0 +1,84 @@
+package pluginmanagerbaselib
+
+import (
+	"crypto/tls"
+	"testing"
+)
+
+func TestVerifyTrustedServerCertAcceptsTrustedLeaf(t *testing.T) {
+	trusted := [][]byte{[]byte("cert-a"), []byte("cert-b")}
+	verify := verifyTrustedServerCert(trusted)
+
+	if err := verify([][]byte{[]byte("cert-b")}, nil); err != nil {
+		t.Fatalf("叶子证书在受信任集合中时不应返回错误: %v", err)
+	}
+}
+
+func TestVerifyTrustedServerCertRejectsUntrustedLeaf(t *testing.T) {
+	verify := verifyTrustedServerCert([][]byte{[]byte("cert-a")})
+
+	if err := verify([][]byte{[]byte("cert-c")}, nil); err == nil {
+		t.Fatalf("叶子证书不在受信任集合中时应返回错误")
+	}
+}
+
+func TestVerifyTrustedServerCertRejectsMissingCert(t *testing.T) {
+	verify := verifyTrustedServerCert([][]byte{[]byte("cert-a")})
+
+	if err := verify(nil, nil); err == nil {
+		t.Fatalf("插件服务端未提供证书时应返回错误")
+	}
+}
+
+func TestPinnedTLSConfigReturnsOriginalWhenNoTrustedCerts(t *testing.T) {
+	p := &PluginInfo{TLSConfig: &tls.Config{ServerName: "example"}}
+
+	cfg, err := p.pinnedTLSConfig()
+	if err != nil {
+		t.Fatalf("未设置TrustedServerCerts时不应返回错误: %v", err)
+	}
+	if cfg != p.TLSConfig {
+		t.Fatalf("未设置TrustedServerCerts时应原样返回p.TLSConfig")
+	}
+}
+
+func TestPinnedTLSConfigRejectsAutoMTLS(t *testing.T) {
+	p := &PluginInfo{
+		AutoMTLS:           true,
+		TLSConfig:          &tls.Config{},
+		TrustedServerCerts: [][]byte{[]byte("cert-a")},
+	}
+
+	if _, err := p.pinnedTLSConfig(); err == nil {
+		t.Fatalf("AutoMTLS模式下设置TrustedServerCerts应返回错误")
+	}
+}
+
+func TestPinnedTLSConfigRequiresTLSConfig(t *testing.T) {
+	p := &PluginInfo{TrustedServerCerts: [][]byte{[]byte("cert-a")}}
+
+	if _, err := p.pinnedTLSConfig(); err == nil {
+		t.Fatalf("未设置TLSConfig时启用TrustedServerCerts应返回错误")
+	}
+}
+
+func TestPinnedTLSConfigPinsLeafCert(t *testing.T) {
+	p := &PluginInfo{
+		TLSConfig:          &tls.Config{ServerName: "example"},
+		TrustedServerCerts: [][]byte{[]byte("cert-a")},
+	}
+
+	cfg, err := p.pinnedTLSConfig()
+	if err != nil {
+		t.Fatalf("未预期的错误: %v", err)
+	}
+	if !cfg.InsecureSkipVerify {
+		t.Fatalf("启用证书锁定后应设置InsecureSkipVerify以跳过默认校验")
+	}
+	if cfg.VerifyPeerCertificate == nil {
+		t.Fatalf("启用证书锁定后应设置VerifyPeerCertificate")
+	}
+	if cfg == p.TLSConfig {
+		t.Fatalf("应返回克隆后的TLSConfig, 而不是直接修改原始配置")
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"crypto/tls"
 	"fmt"
+	"github.com/fsnotify/fsnotify"
 	"github.com/go-base-lib/coderutils"
 	"github.com/hashicorp/go-plugin"
 	"google.golang.org/grpc"
@@ -28,13 +29,30 @@ type PluginInfo struct {
 	// SecureConfig 安全配置
 	SecureConfig *plugin.SecureConfig
 
+	// SignatureConfig SM2签名校验配置, 为nil时不进行签名校验
+	SignatureConfig *SignatureConfig
+
 	// TLSConfig is used to enable TLS on the RPC client.
 	TLSConfig *tls.Config
 
+	// AutoMTLS 为true时, 客户端与插件进程之间自动协商mTLS: 插件进程每次启动都会生成一套临时
+	// 证书/私钥, 客户端自动进行证书锁定, 避免同一台主机上的其它进程能够连接到插件监听的端口
+	AutoMTLS bool
+
+	// TrustedServerCerts 受信任的插件服务端证书(DER编码)集合, 不为空时在连接插件的TLS握手中
+	// 校验其出示的叶子证书是否包含在该集合中, 校验不通过则握手失败、连接无法建立; start()本身
+	// 没有返回值, 不会返回该错误. 与AutoMTLS互斥, 需要配合TLSConfig一起使用, 可通过
+	// LoadTrustedCertsDir加载
+	TrustedServerCerts [][]byte
+
 	// StartTimeout is the timeout to wait for the plugin to say it
 	// has started successfully.
 	StartTimeout time.Duration
 
+	// Reattach 不为nil时, 客户端不再启动新的插件进程, 而是连接到该配置描述的已在运行的进程,
+	// 由Manager结合持久化的ReattachConfig文件使用, 避免宿主进程重启导致插件被杀死
+	Reattach *plugin.ReattachConfig
+
 	//  PrefixCmdAndArgs 前置命令以及参数, 例如: ["java", "-jar"]
 	PrefixCmdAndArgs []string
 
@@ -47,124 +65,275 @@ type PluginInfo struct {
 	// GRPCDialOptions grpc连接选项
 	GRPCDialOptions []grpc.DialOption
 
-	// client 客户端
-	client *plugin.Client
-	rpcCli plugin.ClientProtocol
+	// Handlers 仅对当前插件生效的事件观察者, 与RegisterEventHandler注册的全局观察者共同触发
+	Handlers []EventHandler
+
+	// HealthCheckInterval 健康检查间隔, 小于等于0时使用默认值30秒
+	HealthCheckInterval time.Duration
+
+	// RestartPolicy 插件异常退出后的退避重启与熔断策略, 各字段为0时使用默认值
+	RestartPolicy RestartPolicy
 
-	err       error
-	pluginSet plugin.PluginSet
-	stop      bool
+	// WatchFile 为true时, 监听PluginFilePath的变更, 变更后的文件通过SecureConfig校验即自动
+	// 调用ReplacePlugin完成零停机升级
+	WatchFile bool
 
-	// listenSignal 监听信号
-	listenSignal chan struct{}
+	// client 客户端
+	client      *plugin.Client
+	rpcCli      plugin.ClientProtocol
+	healthCheck func(plugin.ClientProtocol) error
+	watcher     *fsnotify.Watcher
+
+	err                error
+	pluginSet          plugin.PluginSet
+	typeName           string
+	stop               bool
+	lastPing           time.Time
+	restartCount       int
+	unhealthy          bool
+	failureCount       int
+	failureWindowStart time.Time
+
+	// done 用于通知当前正在运行的listen()退出, close(done)即触发退出; 为nil表示当前未在监听
+	done chan struct{}
 }
 
 func (p *PluginInfo) start() {
 	p.lock.Lock()
 	defer p.lock.Unlock()
 
-	p.close()
+	if p.unhealthy {
+		p.err = fmt.Errorf("插件[%s]已熔断, 需调用RestartPlugin显式恢复", p.Id)
+		return
+	}
+
+	// 重新拉起前先清理上一次运行留下的状态
+	p.closeLocked()
 
 	p.stop = false
 
+	if err := p.launchLocked(); err != nil {
+		return
+	}
+
+	done := make(chan struct{})
+	p.done = done
+	go p.listen(done)
+
+	if p.WatchFile {
+		p.watchFile()
+	}
+}
+
+// listen 监督循环, 由start()以独立goroutine启动, 持续对插件进行健康检查; done被关闭时退出
+func (p *PluginInfo) listen(done chan struct{}) {
+	for {
+		timeout := time.After(p.healthCheckInterval())
+		select {
+		case <-done:
+			return
+		case <-timeout:
+			if err := p.probe(); err != nil {
+				p.emitHealthCheckFailed(err)
+
+				tripped, attempt := p.tripCircuitBreaker()
+				if tripped {
+					return
+				}
+
+				if !p.waitBackoff(attempt, done) {
+					return
+				}
+
+				if !p.reconnect() {
+					return
+				}
+
+				continue
+			}
+
+			p.lock.Lock()
+			p.lastPing = time.Now()
+			p.failureCount = 0
+			p.failureWindowStart = time.Time{}
+			p.lock.Unlock()
+		}
+	}
+
+}
+
+// probe 执行一次健康检查, 优先使用SetHealthCheck设置的自定义探测函数, 否则退化为Ping()
+func (p *PluginInfo) probe() error {
+	p.lock.Lock()
+	client := p.client
+	rpcCli := p.rpcCli
+	healthCheck := p.healthCheck
+	p.lock.Unlock()
+
+	if client == nil || client.Exited() || rpcCli == nil {
+		return fmt.Errorf("插件进程未运行")
+	}
+
+	if healthCheck != nil {
+		return healthCheck(rpcCli)
+	}
+
+	return rpcCli.Ping()
+}
+
+// healthCheckInterval 返回健康检查间隔, 未设置时使用默认值30秒
+func (p *PluginInfo) healthCheckInterval() time.Duration {
+	if p.HealthCheckInterval <= 0 {
+		return defaultHealthCheckInterval
+	}
+	return p.HealthCheckInterval
+}
+
+// reconnect 在不退出监督循环的前提下原地重新拉起插件进程. 若插件在此期间已被Remove/Replace
+// 摘除(p.stop为true)则返回false, 调用方应据此退出监督循环; 拉起本身失败不影响返回值,
+// 留待下一轮健康检查探测到故障后继续计入熔断统计
+func (p *PluginInfo) reconnect() bool {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	if p.stop {
+		return false
+	}
+
+	p.teardownClientLocked()
+	_ = p.launchLocked()
+
+	if p.WatchFile && p.client != nil {
+		p.watchFile()
+	}
+
+	return true
+}
+
+// launchLocked 校验插件文件并建立与插件进程的RPC连接, 假定调用方已持有p.lock, 返回时仍然
+// 持有p.lock(与进入时状态一致). 触发OnVerifyFailed/OnStart回调前会短暂释放p.lock、回调结束
+// 后重新加锁, 避免用户回调中重入p.status()等需要加锁的方法时与此处死锁, 失败时将错误写入
+// p.err后返回该错误, 供start()与reconnect()复用
+func (p *PluginInfo) launchLocked() error {
 	if p.SecureConfig != nil && p.SecureConfig.Hash != nil && p.SecureConfig.Checksum != nil {
 		hResult, err := coderutils.HashByFilePath(p.SecureConfig.Hash, p.PluginFilePath)
 		if err != nil {
 			p.err = err
-			return
+			return err
 		}
 
 		if !bytes.Equal(hResult, p.SecureConfig.Checksum) {
 			p.err = fmt.Errorf("插件文件[%s]与预期的HASH不一致", p.PluginFilePath)
-			return
+			p.emitVerifyFailedUnlocked(p.err)
+			return p.err
 		}
 	}
 
+	if err := p.verifySignature(); err != nil {
+		p.err = err
+		p.emitVerifyFailedUnlocked(err)
+		return err
+	}
+
+	tlsConfig, err := p.pinnedTLSConfig()
+	if err != nil {
+		p.err = err
+		return err
+	}
+
 	p.client = plugin.NewClient(&plugin.ClientConfig{
 		HandshakeConfig:  p.HandshakeConfig,
 		Plugins:          p.pluginSet,
 		AllowedProtocols: p.AllowedProtocols,
 		VersionedPlugins: p.VersionedPlugins,
-		TLSConfig:        p.TLSConfig,
+		TLSConfig:        tlsConfig,
+		AutoMTLS:         p.AutoMTLS,
 		StartTimeout:     p.StartTimeout,
 		GRPCDialOptions:  p.GRPCDialOptions,
+		Reattach:         p.Reattach,
 	})
 
 	p.rpcCli, p.err = p.client.Client()
 	if p.err != nil {
-		return
+		return p.err
 	}
-	go p.listen()
+
+	p.emitStartUnlocked()
+	return nil
 }
 
-func (p *PluginInfo) listen() {
+// emitVerifyFailedUnlocked 在调用方已持有p.lock的前提下, 释放锁并触发OnVerifyFailed回调,
+// 回调结束后重新加锁, 供launchLocked复用
+func (p *PluginInfo) emitVerifyFailedUnlocked(err error) {
+	p.lock.Unlock()
+	p.emitVerifyFailed(err)
 	p.lock.Lock()
-	if p.listenSignal != nil {
-		return
-	}
-	p.listenSignal = make(chan struct{}, 1)
+}
+
+// emitStartUnlocked 在调用方已持有p.lock的前提下, 释放锁并触发OnStart回调, 回调结束后
+// 重新加锁, 供launchLocked复用
+func (p *PluginInfo) emitStartUnlocked() {
 	p.lock.Unlock()
+	p.emitStart()
+	p.lock.Lock()
+}
 
-	for {
-		timeout := time.After(30 * time.Second)
-		select {
-		case <-p.listenSignal:
-			p.listenSignal <- struct{}{}
-			return
-		case <-timeout:
-			if p.client == nil || p.client.Exited() || p.rpcCli == nil {
-				p.start()
-				continue
-			}
+// teardownClientLocked 关闭当前客户端连接与文件监听, 但不触碰监督循环的运行状态(done/stop),
+// 假定调用方已持有p.lock, 供closeLocked与reconnect在重新拉起前复用
+func (p *PluginInfo) teardownClientLocked() {
+	if p.watcher != nil {
+		_ = p.watcher.Close()
+		p.watcher = nil
+	}
 
-			if err := p.rpcCli.Ping(); err != nil {
-				p.start()
-				continue
-			}
-		}
+	if p.rpcCli != nil {
+		_ = p.rpcCli.Close()
 	}
+	p.rpcCli = nil
 
+	if p.client != nil {
+		p.client.Kill()
+	}
+	p.client = nil
 }
 
-func (p *PluginInfo) cancelListen() {
-	if p.lock.TryLock() {
-		defer p.lock.Unlock()
+// closeLocked 彻底终止插件运行: 结束监督循环、关闭客户端连接与文件监听. 假定调用方已持有p.lock.
+// 仅执行停止本身, 不触发OnStop(由调用方按需决定是否属于主动停止); 返回true表示本次调用
+// 确实执行了停止(此前并非已停止状态)
+func (p *PluginInfo) closeLocked() bool {
+	if p.stop {
+		return false
 	}
 
-	if p.listenSignal == nil {
-		return
+	if p.done != nil {
+		close(p.done)
+		p.done = nil
 	}
 
-	p.listenSignal <- struct{}{}
-	<-p.listenSignal
-	close(p.listenSignal)
-	p.listenSignal = nil
+	p.teardownClientLocked()
+	p.stop = true
+	return true
 }
 
+// close 加锁后调用closeLocked, 仅终止运行, 不触发OnStop. start()重新拉起前的清理已持有p.lock,
+// 直接调用closeLocked而不经过此方法
 func (p *PluginInfo) close() {
-	if p.lock.TryLock() {
-		defer p.lock.Unlock()
-	}
-
-	if p.stop {
-		return
-	}
-
-	p.cancelListen()
+	p.lock.Lock()
+	defer p.lock.Unlock()
 
-	if p.rpcCli != nil {
-		_ = p.rpcCli.Close()
-	}
+	p.closeLocked()
+}
 
-	p.rpcCli = nil
+// stopAndNotify 彻底终止插件运行, 并在确有实例被停止时触发OnStop. reason按
+// EventHandler.OnStop的约定传入: 主动停止(如RemovePlugin)传nil, 因异常退出而停止传对应错误
+func (p *PluginInfo) stopAndNotify(reason error) {
+	p.lock.Lock()
+	didStop := p.closeLocked()
+	p.lock.Unlock()
 
-	if p.client == nil {
-		p.client.Kill()
+	if didStop {
+		p.emitStop(reason)
 	}
-
-	p.client = nil
-
-	p.stop = true
 }
 
 func (p *PluginInfo) IsStop() bool {
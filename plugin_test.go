@@ -0,0 +1,51 @@
+package pluginmanagerbaselib
+
+import (
+	"crypto/sha256"
+	"github.com/hashicorp/go-plugin"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// TestLaunchLockedEmitsVerifyFailedWithoutHoldingLock 复现一个在OnVerifyFailed回调中调用
+// p.status()的观察者曾经触发的死锁: launchLocked假定调用方已持有p.lock, 若触发
+// emitVerifyFailed时不先释放该锁, p.status()重入加锁会永远阻塞
+func TestLaunchLockedEmitsVerifyFailedWithoutHoldingLock(t *testing.T) {
+	h := &statusQueryingHandler{}
+
+	path := filepath.Join(t.TempDir(), "plugin.bin")
+	if err := os.WriteFile(path, []byte("plugin-binary-content"), 0644); err != nil {
+		t.Fatalf("写入插件文件失败: %v", err)
+	}
+
+	p := &PluginInfo{
+		Id:             "test",
+		PluginFilePath: path,
+		SecureConfig: &plugin.SecureConfig{
+			Checksum: []byte("与实际内容不一致的哈希值"),
+			Hash:     sha256.New(),
+		},
+		Handlers: []EventHandler{h},
+	}
+	h.p = p
+
+	done := make(chan struct{})
+	go func() {
+		p.lock.Lock()
+		_ = p.launchLocked()
+		p.lock.Unlock()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatalf("触发emitVerifyFailed时应已释放p.lock, 实际与OnVerifyFailed回调中的p.status()发生死锁")
+	}
+
+	if !h.called {
+		t.Fatalf("HASH校验未通过时应调用OnVerifyFailed")
+	}
+}
@@ -0,0 +1,56 @@
+package pluginmanagerbaselib
+
+import (
+	"fmt"
+)
+
+// ReplacePlugin 将typeName类型下与info.Id相同的插件替换为info描述的新版本. 新插件会先启动并
+// 通过一次健康检查, 确认可用后才将旧插件摘除并关闭, 以实现不中断服务的热替换
+func ReplacePlugin(typeName string, info *PluginInfo) error {
+	if info == nil {
+		return fmt.Errorf("插件信息不能为空")
+	}
+
+	if info.Id == "" {
+		return fmt.Errorf("插件ID不能为空")
+	}
+
+	old, err := findPluginInfo(typeName, info.Id)
+	if err != nil {
+		return err
+	}
+
+	lock.Lock()
+	pluginSet, ok := pluginTypeMap[typeName]
+	lock.Unlock()
+	if !ok {
+		return fmt.Errorf("未识别的插件类别: %s", typeName)
+	}
+	if _, ok = pluginSet[info.Name]; !ok {
+		return fmt.Errorf("未被注册的插件名称: %s", info.Name)
+	}
+
+	info.typeName = typeName
+	info.start()
+	if info.err != nil {
+		return fmt.Errorf("新插件[%s]启动失败: %w", info.Id, info.err)
+	}
+
+	if err = info.probe(); err != nil {
+		info.stopAndNotify(err)
+		return fmt.Errorf("新插件[%s]健康检查未通过: %w", info.Id, err)
+	}
+
+	lock.Lock()
+	pluginList := pluginInfoMap[typeName]
+	for i, p := range pluginList {
+		if p.Id == info.Id {
+			pluginList[i] = info
+			break
+		}
+	}
+	lock.Unlock()
+
+	old.stopAndNotify(nil)
+	return nil
+}
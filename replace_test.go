@@ -0,0 +1,24 @@
+package pluginmanagerbaselib
+
+import "testing"
+
+func TestReplacePluginRejectsNilInfo(t *testing.T) {
+	if err := ReplacePlugin("anyType", nil); err == nil {
+		t.Fatalf("info为nil时应返回错误")
+	}
+}
+
+func TestReplacePluginRejectsEmptyId(t *testing.T) {
+	if err := ReplacePlugin("anyType", &PluginInfo{Name: "svc"}); err == nil {
+		t.Fatalf("Id为空时应返回错误")
+	}
+}
+
+func TestReplacePluginRejectsUnknownPlugin(t *testing.T) {
+	const typeName = "replacePluginRejectsUnknownTestType"
+	AddPluginTypeInterface(typeName, "svc", stubPlugin{})
+
+	if err := ReplacePlugin(typeName, &PluginInfo{Id: "missing", Name: "svc"}); err == nil {
+		t.Fatalf("替换一个不存在的插件Id时应返回错误")
+	}
+}
@@ -0,0 +1,93 @@
+package pluginmanagerbaselib
+
+import (
+	"encoding/asn1"
+	"fmt"
+	"github.com/tjfoc/gmsm/sm2"
+	"github.com/tjfoc/gmsm/x509"
+	"math/big"
+	"os"
+)
+
+// defaultSM2UserID SM2签名默认的用户标识, 与国密规范一致
+var defaultSM2UserID = []byte("1234567812345678")
+
+// TrustedSigners 按插件Id存放受信任的发布签名公钥, 供SignatureConfig.PublicKey未单独指定时
+// 回退使用, 以便运维团队统一对整个插件集群强制实行"只信任我方发布密钥"的策略
+var TrustedSigners = make(map[string]*sm2.PublicKey)
+
+// SignatureConfig SM2签名校验配置, 相较于SecureConfig的HASH校验, 能够防止攻击者在篡改插件
+// 文件后一并重新计算HASH写入清单文件的情况
+type SignatureConfig struct {
+	// PublicKey 验签公钥, 为nil时回退使用TrustedSigners[PluginInfo.Id]
+	PublicKey *sm2.PublicKey
+	// SignaturePath 插件二进制文件对应的分离签名文件路径
+	SignaturePath string
+	// UserID SM2签名使用的用户标识, 为nil时使用国密默认值1234567812345678
+	UserID []byte
+}
+
+// sm2Signature 插件签名文件的ASN1编码结构, 与标准SM2签名格式(SEQUENCE{r, s})一致
+type sm2Signature struct {
+	R, S *big.Int
+}
+
+// verifySignature 按SignatureConfig校验插件文件的SM2签名, 未配置SignatureConfig时直接放行
+func (p *PluginInfo) verifySignature() error {
+	if p.SignatureConfig == nil {
+		return nil
+	}
+
+	pubKey := p.SignatureConfig.PublicKey
+	if pubKey == nil {
+		pubKey = TrustedSigners[p.Id]
+	}
+	if pubKey == nil {
+		return fmt.Errorf("插件[%s]未配置签名验证公钥", p.Id)
+	}
+
+	if p.SignatureConfig.SignaturePath == "" {
+		return fmt.Errorf("插件[%s]未配置签名文件路径", p.Id)
+	}
+
+	sigBytes, err := os.ReadFile(p.SignatureConfig.SignaturePath)
+	if err != nil {
+		return fmt.Errorf("读取插件签名文件[%s]失败: %w", p.SignatureConfig.SignaturePath, err)
+	}
+
+	fileBytes, err := os.ReadFile(p.PluginFilePath)
+	if err != nil {
+		return fmt.Errorf("读取插件文件[%s]失败: %w", p.PluginFilePath, err)
+	}
+
+	var sig sm2Signature
+	if _, err = asn1.Unmarshal(sigBytes, &sig); err != nil {
+		return fmt.Errorf("解析插件签名文件[%s]失败: %w", p.SignatureConfig.SignaturePath, err)
+	}
+
+	uid := p.SignatureConfig.UserID
+	if len(uid) == 0 {
+		uid = defaultSM2UserID
+	}
+
+	if !sm2.Sm2Verify(pubKey, fileBytes, uid, sig.R, sig.S) {
+		return fmt.Errorf("插件[%s]签名校验未通过", p.Id)
+	}
+
+	return nil
+}
+
+// LoadSM2PublicKeyPEM 从PEM编码文件中加载SM2公钥, 用于填充SignatureConfig.PublicKey或TrustedSigners
+func LoadSM2PublicKeyPEM(path string) (*sm2.PublicKey, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("读取SM2公钥文件[%s]失败: %w", path, err)
+	}
+
+	pubKey, err := x509.ReadPublicKeyFromPem(data)
+	if err != nil {
+		return nil, fmt.Errorf("解析SM2公钥文件[%s]失败: %w", path, err)
+	}
+
+	return pubKey, nil
+}
@@ -0,0 +1,160 @@
+package pluginmanagerbaselib
+
+import (
+	"crypto/rand"
+	"encoding/asn1"
+	"github.com/tjfoc/gmsm/sm2"
+	"github.com/tjfoc/gmsm/x509"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// signTestFile 对content生成SM2签名并以ASN1编码写入dir目录下的签名文件, 返回其路径
+func signTestFile(t *testing.T, dir string, priv *sm2.PrivateKey, uid, content []byte) string {
+	t.Helper()
+
+	r, s, err := sm2.Sm2Sign(priv, content, uid, rand.Reader)
+	if err != nil {
+		t.Fatalf("签名失败: %v", err)
+	}
+
+	data, err := asn1.Marshal(sm2Signature{R: r, S: s})
+	if err != nil {
+		t.Fatalf("编码签名失败: %v", err)
+	}
+
+	sigPath := filepath.Join(dir, "plugin.sig")
+	if err = os.WriteFile(sigPath, data, 0644); err != nil {
+		t.Fatalf("写入签名文件失败: %v", err)
+	}
+
+	return sigPath
+}
+
+func TestVerifySignaturePassesWithValidSignature(t *testing.T) {
+	priv, err := sm2.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatalf("生成SM2密钥对失败: %v", err)
+	}
+
+	dir := t.TempDir()
+	pluginPath := filepath.Join(dir, "plugin.bin")
+	content := []byte("plugin-binary-content")
+	if err = os.WriteFile(pluginPath, content, 0644); err != nil {
+		t.Fatalf("写入插件文件失败: %v", err)
+	}
+
+	sigPath := signTestFile(t, dir, priv, defaultSM2UserID, content)
+
+	p := &PluginInfo{
+		Id:             "test",
+		PluginFilePath: pluginPath,
+		SignatureConfig: &SignatureConfig{
+			PublicKey:     &priv.PublicKey,
+			SignaturePath: sigPath,
+		},
+	}
+
+	if err = p.verifySignature(); err != nil {
+		t.Fatalf("合法签名不应校验失败: %v", err)
+	}
+}
+
+func TestVerifySignatureFailsWhenFileTampered(t *testing.T) {
+	priv, err := sm2.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatalf("生成SM2密钥对失败: %v", err)
+	}
+
+	dir := t.TempDir()
+	pluginPath := filepath.Join(dir, "plugin.bin")
+	if err = os.WriteFile(pluginPath, []byte("plugin-binary-content"), 0644); err != nil {
+		t.Fatalf("写入插件文件失败: %v", err)
+	}
+
+	sigPath := signTestFile(t, dir, priv, defaultSM2UserID, []byte("plugin-binary-content"))
+
+	if err = os.WriteFile(pluginPath, []byte("tampered-content"), 0644); err != nil {
+		t.Fatalf("篡改插件文件失败: %v", err)
+	}
+
+	p := &PluginInfo{
+		Id:             "test",
+		PluginFilePath: pluginPath,
+		SignatureConfig: &SignatureConfig{
+			PublicKey:     &priv.PublicKey,
+			SignaturePath: sigPath,
+		},
+	}
+
+	if err = p.verifySignature(); err == nil {
+		t.Fatalf("插件文件被篡改后签名校验应失败")
+	}
+}
+
+func TestVerifySignatureSkippedWhenNotConfigured(t *testing.T) {
+	p := &PluginInfo{Id: "test"}
+
+	if err := p.verifySignature(); err != nil {
+		t.Fatalf("未配置SignatureConfig时应直接放行, 实际返回: %v", err)
+	}
+}
+
+func TestVerifySignatureFallsBackToTrustedSigners(t *testing.T) {
+	priv, err := sm2.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatalf("生成SM2密钥对失败: %v", err)
+	}
+
+	dir := t.TempDir()
+	pluginPath := filepath.Join(dir, "plugin.bin")
+	content := []byte("plugin-binary-content")
+	if err = os.WriteFile(pluginPath, content, 0644); err != nil {
+		t.Fatalf("写入插件文件失败: %v", err)
+	}
+
+	sigPath := signTestFile(t, dir, priv, defaultSM2UserID, content)
+
+	const id = "trustedSignerTestPlugin"
+	TrustedSigners[id] = &priv.PublicKey
+	defer delete(TrustedSigners, id)
+
+	p := &PluginInfo{
+		Id:             id,
+		PluginFilePath: pluginPath,
+		SignatureConfig: &SignatureConfig{
+			SignaturePath: sigPath,
+		},
+	}
+
+	if err = p.verifySignature(); err != nil {
+		t.Fatalf("应回退使用TrustedSigners中的公钥, 实际返回: %v", err)
+	}
+}
+
+func TestLoadSM2PublicKeyPEMRoundTrip(t *testing.T) {
+	priv, err := sm2.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatalf("生成SM2密钥对失败: %v", err)
+	}
+
+	pemData, err := x509.WritePublicKeyToPem(&priv.PublicKey)
+	if err != nil {
+		t.Fatalf("编码公钥PEM失败: %v", err)
+	}
+
+	path := filepath.Join(t.TempDir(), "pub.pem")
+	if err = os.WriteFile(path, pemData, 0644); err != nil {
+		t.Fatalf("写入公钥PEM文件失败: %v", err)
+	}
+
+	pubKey, err := LoadSM2PublicKeyPEM(path)
+	if err != nil {
+		t.Fatalf("加载公钥PEM失败: %v", err)
+	}
+
+	if pubKey.X.Cmp(priv.PublicKey.X) != 0 || pubKey.Y.Cmp(priv.PublicKey.Y) != 0 {
+		t.Fatalf("加载的公钥与原始公钥不一致")
+	}
+}
@@ -0,0 +1,152 @@
+package pluginmanagerbaselib
+
+import (
+	"fmt"
+	"github.com/hashicorp/go-plugin"
+	"math/rand"
+	"time"
+)
+
+// defaultHealthCheckInterval 默认健康检查间隔
+const defaultHealthCheckInterval = 30 * time.Second
+
+// RestartPolicy 插件异常退出后的退避重启与熔断策略
+type RestartPolicy struct {
+	// MaxRestarts Window窗口内允许的最大连续重启失败次数, 超过后触发熔断, 默认5次
+	MaxRestarts int
+	// Window 统计连续重启失败次数的滑动窗口, 默认1分钟
+	Window time.Duration
+	// BackoffBase 退避基准时长, 默认1秒
+	BackoffBase time.Duration
+	// BackoffMax 退避最大时长, 默认30秒
+	BackoffMax time.Duration
+	// Jitter 退避抖动比例, 实际退避时长在[backoff*(1-Jitter), backoff*(1+Jitter)]间随机, 默认0.2
+	Jitter float64
+}
+
+// defaultRestartPolicy 默认的重启策略
+func defaultRestartPolicy() RestartPolicy {
+	return RestartPolicy{
+		MaxRestarts: 5,
+		Window:      time.Minute,
+		BackoffBase: time.Second,
+		BackoffMax:  30 * time.Second,
+		Jitter:      0.2,
+	}
+}
+
+// restartPolicy 返回p.RestartPolicy与默认值合并后的结果
+func (p *PluginInfo) restartPolicy() RestartPolicy {
+	policy := p.RestartPolicy
+	def := defaultRestartPolicy()
+
+	if policy.MaxRestarts <= 0 {
+		policy.MaxRestarts = def.MaxRestarts
+	}
+	if policy.Window <= 0 {
+		policy.Window = def.Window
+	}
+	if policy.BackoffBase <= 0 {
+		policy.BackoffBase = def.BackoffBase
+	}
+	if policy.BackoffMax <= 0 {
+		policy.BackoffMax = def.BackoffMax
+	}
+	if policy.Jitter <= 0 {
+		policy.Jitter = def.Jitter
+	}
+
+	return policy
+}
+
+// tripCircuitBreaker 统计Window窗口内的连续探测失败次数(attempt), 超出RestartPolicy.MaxRestarts
+// 时将插件置为不健康终态并返回tripped=true, 调用方此时应停止自动重启, 等待显式调用
+// RestartPlugin恢复. attempt会在一次成功的健康探测后被重置为0, 与插件生命周期内累计重启
+// 次数(restartCount)是两个独立的计数, 供waitBackoff计算退避指数时使用
+func (p *PluginInfo) tripCircuitBreaker() (tripped bool, attempt int) {
+	p.lock.Lock()
+
+	policy := p.restartPolicy()
+	now := time.Now()
+
+	if p.failureWindowStart.IsZero() || now.Sub(p.failureWindowStart) > policy.Window {
+		p.failureWindowStart = now
+		p.failureCount = 0
+	}
+
+	p.failureCount++
+	attempt = p.failureCount
+	if attempt <= policy.MaxRestarts {
+		p.lock.Unlock()
+		return false, attempt
+	}
+
+	p.unhealthy = true
+	p.err = fmt.Errorf("插件[%s]在%s内连续重启失败%d次, 已进入不健康终态", p.Id, policy.Window, attempt)
+	p.closeLocked()
+	reason := p.err
+	p.lock.Unlock()
+
+	// emitStop回调用户代码, 与stopAndNotify一样必须在释放p.lock之后触发, 否则回调中若重入
+	// p.status()等需要加锁的方法会与此处已持有的锁发生死锁
+	p.emitStop(reason)
+	return true, attempt
+}
+
+// waitBackoff 按指数退避(附带抖动)等待, attempt为tripCircuitBreaker返回的连续失败次数,
+// 用于计算退避指数. 等待期间done被关闭(插件被Remove/Replace摘除)则立即返回false,
+// 调用方应据此退出监督循环, 而不是在插件已被摘除后仍尝试重新拉起
+func (p *PluginInfo) waitBackoff(attempt int, done <-chan struct{}) bool {
+	policy := p.restartPolicy()
+
+	p.lock.Lock()
+	p.restartCount++
+	restartCount := p.restartCount
+	p.lock.Unlock()
+	p.emitRestart(restartCount)
+
+	backoff := policy.BackoffBase << uint(attempt-1)
+	if backoff <= 0 || backoff > policy.BackoffMax {
+		backoff = policy.BackoffMax
+	}
+
+	if policy.Jitter > 0 {
+		delta := float64(backoff) * policy.Jitter
+		backoff = backoff - time.Duration(delta) + time.Duration(rand.Float64()*2*delta)
+	}
+
+	timer := time.NewTimer(backoff)
+	defer timer.Stop()
+
+	select {
+	case <-done:
+		return false
+	case <-timer.C:
+		return true
+	}
+}
+
+// SetHealthCheck 设置自定义健康检查函数, 用于替代默认的Ping()探测
+func (p *PluginInfo) SetHealthCheck(f func(plugin.ClientProtocol) error) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	p.healthCheck = f
+}
+
+// RestartPlugin 显式重启typeName类型下Id为id的插件, 用于从熔断产生的不健康终态中恢复
+func RestartPlugin(typeName, id string) error {
+	p, err := findPluginInfo(typeName, id)
+	if err != nil {
+		return err
+	}
+
+	p.lock.Lock()
+	p.unhealthy = false
+	p.failureCount = 0
+	p.failureWindowStart = time.Time{}
+	p.lock.Unlock()
+
+	p.start()
+	return p.err
+}
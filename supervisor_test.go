@@ -0,0 +1,174 @@
+package pluginmanagerbaselib
+
+import (
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestTripCircuitBreakerTripsAfterMaxRestarts(t *testing.T) {
+	p := &PluginInfo{
+		Id: "test",
+		RestartPolicy: RestartPolicy{
+			MaxRestarts: 2,
+			Window:      time.Minute,
+		},
+	}
+
+	for i := 0; i < 2; i++ {
+		tripped, attempt := p.tripCircuitBreaker()
+		if tripped {
+			t.Fatalf("第%d次失败不应触发熔断", i+1)
+		}
+		if attempt != i+1 {
+			t.Fatalf("期望连续失败次数为%d, 实际为%d", i+1, attempt)
+		}
+	}
+
+	tripped, attempt := p.tripCircuitBreaker()
+	if !tripped {
+		t.Fatalf("超过MaxRestarts后应触发熔断")
+	}
+	if attempt != 3 {
+		t.Fatalf("期望连续失败次数为3, 实际为%d", attempt)
+	}
+	if !p.unhealthy {
+		t.Fatalf("熔断后应标记为不健康")
+	}
+}
+
+func TestWaitBackoffCancelledByDone(t *testing.T) {
+	p := &PluginInfo{
+		Id: "test",
+		RestartPolicy: RestartPolicy{
+			BackoffBase: time.Hour,
+			BackoffMax:  time.Hour,
+		},
+	}
+
+	done := make(chan struct{})
+	close(done)
+
+	start := time.Now()
+	if p.waitBackoff(1, done) {
+		t.Fatalf("done已关闭时waitBackoff应返回false")
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Fatalf("waitBackoff应被done立即打断, 实际耗时%s", elapsed)
+	}
+}
+
+func TestReconnectBailsWhenStopped(t *testing.T) {
+	p := &PluginInfo{Id: "test"}
+	p.stop = true
+
+	if p.reconnect() {
+		t.Fatalf("插件已被标记为stop时reconnect应返回false")
+	}
+}
+
+// TestCloseInterruptsSleepingSupervisor 复现supervisor在backoff休眠期间被Remove/Replace摘除
+// 的场景: close()必须能够立即唤醒正在waitBackoff中休眠的监督循环, 而不是让其在退避结束后
+// 才发现插件已被摘除并尝试重新拉起一个已被移除的插件
+func TestCloseInterruptsSleepingSupervisor(t *testing.T) {
+	p := &PluginInfo{
+		Id: "test",
+		RestartPolicy: RestartPolicy{
+			BackoffBase: time.Hour,
+			BackoffMax:  time.Hour,
+		},
+	}
+
+	done := make(chan struct{})
+	p.done = done
+
+	var (
+		wg     sync.WaitGroup
+		awoken bool
+	)
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		awoken = p.waitBackoff(1, done)
+	}()
+
+	time.Sleep(50 * time.Millisecond)
+	p.close()
+
+	waitTimeout(t, &wg, time.Second)
+	if awoken {
+		t.Fatalf("close()之后waitBackoff应返回false, 而不是等到退避时长结束")
+	}
+}
+
+// statusQueryingHandler 在每个回调中都重入p.status()(需要再次获取p.lock), 用于复现"emit类
+// 回调在调用方仍持有p.lock期间触发"导致的死锁: 若emit调用点没有先释放锁, p.status()将永远
+// 拿不到锁
+type statusQueryingHandler struct {
+	p      *PluginInfo
+	called bool
+}
+
+func (h *statusQueryingHandler) OnStart(string) {
+	h.called = true
+	_ = h.p.status()
+}
+func (h *statusQueryingHandler) OnStop(string, error) {
+	h.called = true
+	_ = h.p.status()
+}
+func (h *statusQueryingHandler) OnHealthCheckFailed(string, error) {}
+func (h *statusQueryingHandler) OnRestart(string, int)             {}
+func (h *statusQueryingHandler) OnVerifyFailed(string, error) {
+	h.called = true
+	_ = h.p.status()
+}
+
+// TestTripCircuitBreakerEmitsStopWithoutHoldingLock 复现一个在OnStop回调中调用p.status()的
+// 观察者曾经触发的死锁: tripCircuitBreaker在熔断时必须先释放p.lock再触发emitStop, 否则
+// p.status()重入加锁会永远阻塞
+func TestTripCircuitBreakerEmitsStopWithoutHoldingLock(t *testing.T) {
+	h := &statusQueryingHandler{}
+	p := &PluginInfo{
+		Id: "test",
+		RestartPolicy: RestartPolicy{
+			MaxRestarts: 1,
+			Window:      time.Minute,
+		},
+		Handlers: []EventHandler{h},
+	}
+	h.p = p
+
+	done := make(chan struct{})
+	go func() {
+		p.tripCircuitBreaker()
+		p.tripCircuitBreaker()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatalf("熔断触发emitStop时应已释放p.lock, 实际与OnStop回调中的p.status()发生死锁")
+	}
+
+	if !h.called {
+		t.Fatalf("熔断触发后应调用OnStop")
+	}
+}
+
+func waitTimeout(t *testing.T, wg *sync.WaitGroup, timeout time.Duration) {
+	t.Helper()
+
+	ch := make(chan struct{})
+	go func() {
+		wg.Wait()
+		close(ch)
+	}()
+
+	select {
+	case <-ch:
+	case <-time.After(timeout):
+		t.Fatalf("等待超时")
+	}
+}
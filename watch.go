@@ -0,0 +1,87 @@
+package pluginmanagerbaselib
+
+import (
+	"bytes"
+	"fmt"
+	"github.com/fsnotify/fsnotify"
+	"github.com/go-base-lib/coderutils"
+)
+
+// watchFile 启动对PluginFilePath的监听, 文件发生变更且通过SecureConfig校验后自动调用
+// ReplacePlugin完成零停机升级. 仅在WatchFile为true且尚未启动监听时生效
+func (p *PluginInfo) watchFile() {
+	if p.watcher != nil {
+		return
+	}
+
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return
+	}
+
+	if err = watcher.Add(p.PluginFilePath); err != nil {
+		_ = watcher.Close()
+		return
+	}
+
+	p.watcher = watcher
+
+	go func() {
+		for event := range watcher.Events {
+			if event.Op&(fsnotify.Write|fsnotify.Create) == 0 {
+				continue
+			}
+
+			if err := p.verifySecureConfig(); err != nil {
+				p.emitVerifyFailed(err)
+				continue
+			}
+
+			replacement := p.cloneForUpgrade()
+			_ = ReplacePlugin(p.typeName, replacement)
+		}
+	}()
+}
+
+// verifySecureConfig 校验当前PluginFilePath文件内容是否仍满足SecureConfig声明的HASH
+func (p *PluginInfo) verifySecureConfig() error {
+	if p.SecureConfig == nil || p.SecureConfig.Hash == nil || p.SecureConfig.Checksum == nil {
+		return nil
+	}
+
+	hResult, err := coderutils.HashByFilePath(p.SecureConfig.Hash, p.PluginFilePath)
+	if err != nil {
+		return err
+	}
+
+	if !bytes.Equal(hResult, p.SecureConfig.Checksum) {
+		return fmt.Errorf("插件文件[%s]与预期的HASH不一致", p.PluginFilePath)
+	}
+
+	return nil
+}
+
+// cloneForUpgrade 基于当前配置构建用于热替换的新PluginInfo, 复用除Reattach外的全部导出配置,
+// WatchFile固定为true. 运行时状态以及SetHealthCheck设置的自定义探测函数不会被复制
+func (p *PluginInfo) cloneForUpgrade() *PluginInfo {
+	return &PluginInfo{
+		Id:                  p.Id,
+		Name:                p.Name,
+		HandshakeConfig:     p.HandshakeConfig,
+		VersionedPlugins:    p.VersionedPlugins,
+		SecureConfig:        p.SecureConfig,
+		SignatureConfig:     p.SignatureConfig,
+		TLSConfig:           p.TLSConfig,
+		AutoMTLS:            p.AutoMTLS,
+		TrustedServerCerts:  p.TrustedServerCerts,
+		StartTimeout:        p.StartTimeout,
+		PrefixCmdAndArgs:    p.PrefixCmdAndArgs,
+		PluginFilePath:      p.PluginFilePath,
+		AllowedProtocols:    p.AllowedProtocols,
+		GRPCDialOptions:     p.GRPCDialOptions,
+		Handlers:            p.Handlers,
+		HealthCheckInterval: p.HealthCheckInterval,
+		RestartPolicy:       p.RestartPolicy,
+		WatchFile:           true,
+	}
+}
@@ -0,0 +1,85 @@
+package pluginmanagerbaselib
+
+import (
+	"crypto/sha256"
+	"github.com/hashicorp/go-plugin"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestVerifySecureConfigPassesWithoutSecureConfig(t *testing.T) {
+	p := &PluginInfo{PluginFilePath: filepath.Join(t.TempDir(), "missing")}
+
+	if err := p.verifySecureConfig(); err != nil {
+		t.Fatalf("未配置SecureConfig时应直接放行, 实际返回: %v", err)
+	}
+}
+
+func TestVerifySecureConfigPassesWithMatchingChecksum(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "plugin.bin")
+	content := []byte("plugin-binary-content")
+	if err := os.WriteFile(path, content, 0644); err != nil {
+		t.Fatalf("写入插件文件失败: %v", err)
+	}
+
+	sum := sha256.Sum256(content)
+	p := &PluginInfo{
+		PluginFilePath: path,
+		SecureConfig: &plugin.SecureConfig{
+			Checksum: sum[:],
+			Hash:     sha256.New(),
+		},
+	}
+
+	if err := p.verifySecureConfig(); err != nil {
+		t.Fatalf("HASH一致时不应返回错误: %v", err)
+	}
+}
+
+func TestVerifySecureConfigFailsWithMismatchedChecksum(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "plugin.bin")
+	if err := os.WriteFile(path, []byte("plugin-binary-content"), 0644); err != nil {
+		t.Fatalf("写入插件文件失败: %v", err)
+	}
+
+	p := &PluginInfo{
+		PluginFilePath: path,
+		SecureConfig: &plugin.SecureConfig{
+			Checksum: []byte("不一致的哈希值"),
+			Hash:     sha256.New(),
+		},
+	}
+
+	if err := p.verifySecureConfig(); err == nil {
+		t.Fatalf("HASH不一致时应返回错误")
+	}
+}
+
+func TestCloneForUpgradeCopiesConfigAndForcesWatchFile(t *testing.T) {
+	h := &recordingHandler{}
+	p := &PluginInfo{
+		Id:               "plugin-1",
+		Name:             "svc",
+		PluginFilePath:   "/tmp/plugin-1",
+		PrefixCmdAndArgs: []string{"java", "-jar"},
+		AllowedProtocols: []plugin.Protocol{plugin.ProtocolGRPC},
+		Handlers:         []EventHandler{h},
+		WatchFile:        false,
+	}
+
+	clone := p.cloneForUpgrade()
+
+	if clone.Id != p.Id || clone.Name != p.Name || clone.PluginFilePath != p.PluginFilePath {
+		t.Fatalf("克隆后的基础配置应与原始PluginInfo一致")
+	}
+	if len(clone.Handlers) != 1 || clone.Handlers[0] != h {
+		t.Fatalf("克隆后应沿用原始的Handlers")
+	}
+	if !clone.WatchFile {
+		t.Fatalf("用于热替换的克隆应强制开启WatchFile")
+	}
+	if clone == p {
+		t.Fatalf("cloneForUpgrade应返回一个新的PluginInfo实例")
+	}
+}